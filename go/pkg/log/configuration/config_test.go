@@ -0,0 +1,80 @@
+package configuration
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func validConfig() *LogServiceConfiguration {
+	cfg := defaultLogServiceConfiguration()
+	cfg.Database.Host = "postgres.chroma.svc.cluster.local"
+	return cfg
+}
+
+func TestValidateAcceptsDefaults(t *testing.T) {
+	if err := validConfig().Validate(); err != nil {
+		t.Fatalf("Validate() = %v, want nil", err)
+	}
+}
+
+func TestValidateRejectsMissingPort(t *testing.T) {
+	cfg := validConfig()
+	cfg.PORT = ""
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("Validate() = nil, want an error for missing PORT")
+	}
+}
+
+func TestValidateRejectsUnknownLogLevel(t *testing.T) {
+	cfg := validConfig()
+	cfg.LogLevel = "verbose"
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("Validate() = nil, want an error for an unrecognized LogLevel")
+	}
+}
+
+func TestValidateRejectsTLSEnabledWithoutFiles(t *testing.T) {
+	cfg := validConfig()
+	cfg.TLSEnabled = true
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("Validate() = nil, want an error when TLSEnabled is set without cert/key files")
+	}
+}
+
+func TestValidateRejectsNonPositiveMaxOpenConns(t *testing.T) {
+	cfg := validConfig()
+	cfg.Database.MaxOpenConns = 0
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("Validate() = nil, want an error for Database.MaxOpenConns <= 0")
+	}
+}
+
+// TestNewLogServiceConfigurationLogsDiscardedLoadError guards against
+// silently falling back to defaults: a malformed CHROMA_LOG_CONFIG file
+// should still produce a working default configuration, but the load error
+// that caused the fallback must be logged rather than swallowed.
+func TestNewLogServiceConfigurationLogsDiscardedLoadError(t *testing.T) {
+	dir := t.TempDir()
+	badPath := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(badPath, []byte("port: [not closed\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(configFileEnvVar, badPath)
+
+	var buf bytes.Buffer
+	prevOutput := log.Writer()
+	log.SetOutput(&buf)
+	defer log.SetOutput(prevOutput)
+
+	cfg := NewLogServiceConfiguration()
+
+	if cfg.PORT != "50051" {
+		t.Fatalf("PORT = %q, want default %q after a failed load", cfg.PORT, "50051")
+	}
+	if buf.Len() == 0 {
+		t.Fatal("expected NewLogServiceConfiguration to log the discarded load error, got nothing")
+	}
+}
@@ -0,0 +1,187 @@
+package configuration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Duration is time.Duration with YAML/TOML unmarshaling from strings like
+// "30m" or "2s", so config files can use the same syntax as flags and env
+// vars instead of a raw nanosecond count.
+type Duration time.Duration
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	parsed, err := time.ParseDuration(value.Value)
+	if err != nil {
+		return fmt.Errorf("configuration: invalid duration %q: %w", value.Value, err)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, which the TOML decoder
+// uses for scalar values.
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return fmt.Errorf("configuration: invalid duration %q: %w", text, err)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// dbPasswordFileEnvVar points at a file (typically a Kubernetes secret mount)
+// whose contents override Database.Password, so the password itself never
+// has to be set as a plain environment variable.
+const dbPasswordFileEnvVar = "CHROMA_DB_PASSWORD_FILE"
+
+// DatabaseConfig describes how the log service connects to its Postgres
+// database, including pool sizing and startup retry/backoff.
+type DatabaseConfig struct {
+	Host     string `config:"db-host"     env:"CHROMA_DB_HOST"     yaml:"host"     toml:"host"     default:"postgres.chroma.svc.cluster.local"`
+	Port     int    `config:"db-port"     env:"CHROMA_DB_PORT"     yaml:"port"     toml:"port"     default:"5432"`
+	User     string `config:"db-user"     env:"CHROMA_DB_USER"     yaml:"user"     toml:"user"     default:"chroma"`
+	Password string `config:"db-password" env:"CHROMA_DB_PASSWORD" yaml:"password" toml:"password" default:"chroma"`
+	Database string `config:"db-name"     env:"CHROMA_DB_NAME"     yaml:"database" toml:"database" default:"log"`
+	SSLMode  string `config:"db-sslmode"  env:"CHROMA_DB_SSLMODE"  yaml:"sslmode"  toml:"sslmode"  default:"disable"`
+
+	MaxOpenConns    int      `config:"db-max-open-conns" env:"CHROMA_DB_MAX_OPEN_CONNS" yaml:"max_open_conns" toml:"max_open_conns" default:"10"`
+	MaxIdleConns    int      `config:"db-max-idle-conns" env:"CHROMA_DB_MAX_IDLE_CONNS" yaml:"max_idle_conns" toml:"max_idle_conns" default:"5"`
+	ConnMaxLifetime Duration `config:"db-conn-max-lifetime" env:"CHROMA_DB_CONN_MAX_LIFETIME" yaml:"conn_max_lifetime" toml:"conn_max_lifetime" default:"30m"`
+
+	ConnectRetryAttempts int      `config:"db-connect-retry-attempts" env:"CHROMA_DB_CONNECT_RETRY_ATTEMPTS" yaml:"connect_retry_attempts" toml:"connect_retry_attempts" default:"5"`
+	ConnectRetryBackoff  Duration `config:"db-connect-retry-backoff"  env:"CHROMA_DB_CONNECT_RETRY_BACKOFF"  yaml:"connect_retry_backoff"  toml:"connect_retry_backoff"  default:"2s"`
+}
+
+// DSN renders d as a libpq-style connection string.
+func (d DatabaseConfig) DSN() string {
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		d.Host, d.Port, d.User, d.Password, d.Database, d.SSLMode)
+}
+
+// Connect opens a Postgres connection using d, making up to
+// ConnectRetryAttempts attempts with ConnectRetryBackoff between them. This
+// lets the log service start before Postgres is fully available in a
+// cluster, rather than failing on the first connection attempt.
+func (d DatabaseConfig) Connect(ctx context.Context) (*sql.DB, error) {
+	db, err := sql.Open("pgx", d.DSN())
+	if err != nil {
+		return nil, fmt.Errorf("configuration: opening database: %w", err)
+	}
+	db.SetMaxOpenConns(d.MaxOpenConns)
+	db.SetMaxIdleConns(d.MaxIdleConns)
+	db.SetConnMaxLifetime(time.Duration(d.ConnMaxLifetime))
+
+	attempts := d.ConnectRetryAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if lastErr = db.PingContext(ctx); lastErr == nil {
+			return db, nil
+		}
+		log.Printf("configuration: database ping attempt %d/%d failed: %v", attempt, attempts, lastErr)
+		if attempt == attempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			db.Close()
+			return nil, ctx.Err()
+		case <-time.After(time.Duration(d.ConnectRetryBackoff)):
+		}
+	}
+
+	db.Close()
+	return nil, fmt.Errorf("configuration: database unreachable after %d attempts: %w", attempts, lastErr)
+}
+
+// ParseDatabaseURL parses a postgres:// or postgresql:// connection URL (the
+// CHROMA_DATABASE_URL convenience form) into a DatabaseConfig.
+func ParseDatabaseURL(rawURL string) (DatabaseConfig, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return DatabaseConfig{}, fmt.Errorf("configuration: invalid database URL: %w", err)
+	}
+
+	var db DatabaseConfig
+	db.Host = u.Hostname()
+	if port := u.Port(); port != "" {
+		n, err := strconv.Atoi(port)
+		if err != nil {
+			return DatabaseConfig{}, fmt.Errorf("configuration: invalid database URL port %q: %w", port, err)
+		}
+		db.Port = n
+	}
+	if u.User != nil {
+		db.User = u.User.Username()
+		db.Password, _ = u.User.Password()
+	}
+	db.Database = strings.TrimPrefix(u.Path, "/")
+	db.SSLMode = u.Query().Get("sslmode")
+	return db, nil
+}
+
+// applyDatabaseURL seeds cfg.Database from cfg.DATABASE_URL, filling in only
+// the fields ParseDatabaseURL produces and only where Database still holds
+// its built-in default -- so explicit Database.* env vars or flags continue
+// to take precedence over the convenience URL form.
+func applyDatabaseURL(cfg *LogServiceConfiguration) {
+	if cfg.DATABASE_URL == "" {
+		return
+	}
+	parsed, err := ParseDatabaseURL(cfg.DATABASE_URL)
+	if err != nil {
+		log.Printf("configuration: ignoring %s: %v", cfg.DATABASE_URL, err)
+		return
+	}
+
+	def := defaultLogServiceConfiguration().Database
+	db := &cfg.Database
+	if db.Host == def.Host {
+		db.Host = parsed.Host
+	}
+	if db.Port == def.Port && parsed.Port != 0 {
+		db.Port = parsed.Port
+	}
+	if db.User == def.User {
+		db.User = parsed.User
+	}
+	if db.Password == def.Password {
+		db.Password = parsed.Password
+	}
+	if db.Database == def.Database {
+		db.Database = parsed.Database
+	}
+	if db.SSLMode == def.SSLMode && parsed.SSLMode != "" {
+		db.SSLMode = parsed.SSLMode
+	}
+}
+
+// applyPasswordFile overrides Database.Password from the file named by
+// CHROMA_DB_PASSWORD_FILE, if set, so a mounted Kubernetes secret can supply
+// the password without it ever appearing in the pod's environment.
+func applyPasswordFile(cfg *LogServiceConfiguration) {
+	path := os.Getenv(dbPasswordFileEnvVar)
+	if path == "" {
+		return
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Printf("configuration: reading %s=%s: %v", dbPasswordFileEnvVar, path, err)
+		return
+	}
+	cfg.Database.Password = strings.TrimSpace(string(data))
+}
@@ -0,0 +1,28 @@
+package configuration
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// loadConfigFile reads path and unmarshals it into cfg, choosing YAML or
+// TOML based on the file extension.
+func loadConfigFile(path string, cfg *LogServiceConfiguration) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		return yaml.Unmarshal(data, cfg)
+	case ".toml":
+		return toml.Unmarshal(data, cfg)
+	default:
+		return fmt.Errorf("unsupported config file extension %q", ext)
+	}
+}
@@ -0,0 +1,211 @@
+package configuration
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// durationType is the reflect.Type of Duration, checked explicitly because
+// Duration's underlying Kind is Int64 and would otherwise be mistaken for a
+// plain integer field by setFieldFromString/registerFlag.
+var durationType = reflect.TypeOf(Duration(0))
+
+// configFileEnvVar and configFileFlag let operators point the log service at
+// a config file without rebuilding: CHROMA_LOG_CONFIG=/etc/chroma/log.yaml or
+// --config=/etc/chroma/log.yaml. Neither has to be set. A file that is named
+// but does not exist is an error, except under a CHROMA_ENV profile, where
+// missing files are skipped.
+const (
+	configFileEnvVar = "CHROMA_LOG_CONFIG"
+	configFileFlag   = "config"
+)
+
+// LoadLogServiceConfiguration builds a LogServiceConfiguration by layering,
+// in increasing precedence: struct defaults, a config file (YAML or TOML,
+// chosen by extension), environment variables, and command-line flags. args
+// is typically os.Args[1:]; pass nil to skip flag parsing (e.g. in tests).
+func LoadLogServiceConfiguration(args []string) (*LogServiceConfiguration, error) {
+	cfg := defaultLogServiceConfiguration()
+
+	configPath := firstConfigFlagValue(args, os.Getenv(configFileEnvVar))
+	if env := os.Getenv(profileEnvVar); env != "" {
+		if err := loadProfile(cfg, configPath, env); err != nil {
+			return nil, err
+		}
+	} else {
+		setProfileName("")
+		if configPath != "" {
+			if err := loadConfigFile(configPath, cfg); err != nil {
+				return nil, fmt.Errorf("configuration: loading %s: %w", configPath, err)
+			}
+		}
+	}
+
+	applyEnv(cfg)
+
+	if err := applyFlags(cfg, args); err != nil {
+		return nil, fmt.Errorf("configuration: parsing flags: %w", err)
+	}
+	// Runs after env and flags so a --database-url flag (or CHROMA_DATABASE_URL
+	// env var) is reflected in cfg.Database even though both can also set
+	// cfg.Database.* fields directly.
+	applyDatabaseURL(cfg)
+	applyPasswordFile(cfg)
+
+	return cfg, nil
+}
+
+// defaultLogServiceConfiguration returns a LogServiceConfiguration populated
+// entirely from each field's `default` struct tag.
+func defaultLogServiceConfiguration() *LogServiceConfiguration {
+	cfg := &LogServiceConfiguration{}
+	walkFields(reflect.ValueOf(cfg).Elem(), func(field reflect.Value, tag reflect.StructTag) {
+		if def, ok := tag.Lookup("default"); ok {
+			// Defaults are trusted input from our own struct tags, not user
+			// input, so a malformed default is a programmer error.
+			if err := setFieldFromString(field, def); err != nil {
+				panic(fmt.Sprintf("configuration: invalid default %q: %v", def, err))
+			}
+		}
+	})
+	return cfg
+}
+
+// applyEnv overwrites each field whose `env` tag names a set environment
+// variable.
+func applyEnv(cfg *LogServiceConfiguration) {
+	walkFields(reflect.ValueOf(cfg).Elem(), func(field reflect.Value, tag reflect.StructTag) {
+		key, ok := tag.Lookup("env")
+		if !ok {
+			return
+		}
+		if value, ok := os.LookupEnv(key); ok {
+			if err := setFieldFromString(field, value); err != nil {
+				fmt.Fprintf(os.Stderr, "configuration: ignoring %s=%q: %v\n", key, value, err)
+			}
+		}
+	})
+}
+
+// applyFlags registers one CLI flag per `config` tag (plus --config itself)
+// and overwrites fields whose flag was explicitly passed in args.
+func applyFlags(cfg *LogServiceConfiguration, args []string) error {
+	fs := flag.NewFlagSet("log-service", flag.ContinueOnError)
+	fs.String(configFileFlag, "", "path to a YAML or TOML config file")
+
+	fields := make(map[string]reflect.Value)
+	walkFields(reflect.ValueOf(cfg).Elem(), func(field reflect.Value, tag reflect.StructTag) {
+		name, ok := tag.Lookup("config")
+		if !ok {
+			return
+		}
+		fields[name] = field
+		registerFlag(fs, name, field)
+	})
+
+	if args == nil {
+		return nil
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var firstErr error
+	fs.Visit(func(f *flag.Flag) {
+		field, ok := fields[f.Name]
+		if !ok {
+			return
+		}
+		if err := setFieldFromString(field, f.Value.String()); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("--%s: %w", f.Name, err)
+		}
+	})
+	return firstErr
+}
+
+// registerFlag declares fs's flag for field, picking the FlagSet constructor
+// (String, Bool or Int64) that matches field's Go type.
+func registerFlag(fs *flag.FlagSet, name string, field reflect.Value) {
+	switch {
+	case field.Type() == durationType:
+		fs.String(name, time.Duration(field.Int()).String(), "")
+	case field.Kind() == reflect.Bool:
+		fs.Bool(name, field.Bool(), "")
+	case field.Kind() == reflect.Int, field.Kind() == reflect.Int64:
+		fs.Int64(name, field.Int(), "")
+	default:
+		fs.String(name, fmt.Sprintf("%v", field.Interface()), "")
+	}
+}
+
+// setFieldFromString assigns value to field, converting it to field's Go
+// type.
+func setFieldFromString(field reflect.Value, value string) error {
+	if field.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("expected a duration, got %q", value)
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("expected a bool, got %q", value)
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("expected an integer, got %q", value)
+		}
+		field.SetInt(n)
+	default:
+		return fmt.Errorf("unsupported field kind %s", field.Kind())
+	}
+	return nil
+}
+
+// walkFields visits every leaf (non-struct) field reachable from v, recursing
+// into nested structs so a sub-struct like DatabaseConfig is covered by the
+// same defaults/env/flag machinery as top-level fields.
+func walkFields(v reflect.Value, visit func(field reflect.Value, tag reflect.StructTag)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := v.Field(i)
+		sf := t.Field(i)
+		if field.Kind() == reflect.Struct {
+			walkFields(field, visit)
+			continue
+		}
+		visit(field, sf.Tag)
+	}
+}
+
+// firstConfigFlagValue scans args for --config/-config without fully parsing
+// the flag set, so the config file path is known before the rest of the
+// flags are registered. It falls back to cur when --config is absent.
+func firstConfigFlagValue(args []string, cur string) string {
+	for i, arg := range args {
+		switch {
+		case arg == "--"+configFileFlag || arg == "-"+configFileFlag:
+			if i+1 < len(args) {
+				return args[i+1]
+			}
+		case strings.HasPrefix(arg, "--"+configFileFlag+"="):
+			return strings.TrimPrefix(arg, "--"+configFileFlag+"=")
+		case strings.HasPrefix(arg, "-"+configFileFlag+"="):
+			return strings.TrimPrefix(arg, "-"+configFileFlag+"=")
+		}
+	}
+	return cur
+}
@@ -0,0 +1,55 @@
+package configuration
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSetFieldFromStringString(t *testing.T) {
+	var s string
+	field := reflect.ValueOf(&s).Elem()
+	if err := setFieldFromString(field, "hello"); err != nil {
+		t.Fatalf("setFieldFromString() error = %v", err)
+	}
+	if s != "hello" {
+		t.Fatalf("s = %q, want %q", s, "hello")
+	}
+}
+
+func TestSetFieldFromStringBool(t *testing.T) {
+	var b bool
+	field := reflect.ValueOf(&b).Elem()
+	if err := setFieldFromString(field, "true"); err != nil {
+		t.Fatalf("setFieldFromString() error = %v", err)
+	}
+	if !b {
+		t.Fatal("b = false, want true")
+	}
+	if err := setFieldFromString(field, "not-a-bool"); err == nil {
+		t.Fatal("setFieldFromString() error = nil, want an error for an invalid bool")
+	}
+}
+
+func TestSetFieldFromStringInt(t *testing.T) {
+	var n int
+	field := reflect.ValueOf(&n).Elem()
+	if err := setFieldFromString(field, "42"); err != nil {
+		t.Fatalf("setFieldFromString() error = %v", err)
+	}
+	if n != 42 {
+		t.Fatalf("n = %d, want 42", n)
+	}
+	if err := setFieldFromString(field, "not-an-int"); err == nil {
+		t.Fatal("setFieldFromString() error = nil, want an error for a non-integer int field")
+	}
+}
+
+func TestDefaultLogServiceConfigurationAppliesDefaults(t *testing.T) {
+	cfg := defaultLogServiceConfiguration()
+	if cfg.PORT != "50051" {
+		t.Fatalf("PORT = %q, want %q", cfg.PORT, "50051")
+	}
+	if cfg.LogLevel != "info" {
+		t.Fatalf("LogLevel = %q, want %q", cfg.LogLevel, "info")
+	}
+}
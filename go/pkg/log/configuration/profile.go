@@ -0,0 +1,193 @@
+package configuration
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// profileEnvVar selects a config profile (dev, staging, prod): when set,
+// NewLogServiceConfiguration/LoadLogServiceConfiguration load config.yaml as
+// a base and deep-merge config.<profile>.yaml on top of it, before env vars
+// and flags are applied.
+const profileEnvVar = "CHROMA_ENV"
+
+// profileMu guards profileName, which LoadLogServiceConfiguration can write
+// from concurrent goroutines (e.g. Watch's reload loop racing a caller's own
+// LoadLogServiceConfiguration/ProfileName calls).
+var (
+	profileMu   sync.Mutex
+	profileName string
+)
+
+// ProfileName returns the CHROMA_ENV profile applied to the current
+// configuration ("dev", "staging", "prod"), or "" if CHROMA_ENV was unset.
+func ProfileName() string {
+	profileMu.Lock()
+	defer profileMu.Unlock()
+	return profileName
+}
+
+// setProfileName records name as the most recently applied CHROMA_ENV
+// profile, for ProfileName.
+func setProfileName(name string) {
+	profileMu.Lock()
+	defer profileMu.Unlock()
+	profileName = name
+}
+
+// loadProfile loads config.yaml (or configPath, if one was given via --config
+// / CHROMA_LOG_CONFIG) as a base and deep-merges config.<env>.yaml from the
+// same directory on top of it, logging which files were found.
+func loadProfile(cfg *LogServiceConfiguration, configPath, env string) error {
+	dir, base := ".", "config.yaml"
+	if configPath != "" {
+		dir, base = filepath.Dir(configPath), filepath.Base(configPath)
+	}
+
+	var loaded []string
+
+	basePath := filepath.Join(dir, base)
+	if err := loadConfigFileIfExists(basePath, cfg, &loaded); err != nil {
+		return err
+	}
+
+	ext := filepath.Ext(base)
+	name := strings.TrimSuffix(base, ext)
+	profilePath := filepath.Join(dir, fmt.Sprintf("%s.%s%s", name, env, ext))
+
+	overlay, err := decodeOverlayIfExists(profilePath, ext, &loaded)
+	if err != nil {
+		return err
+	}
+	if overlay != nil {
+		applyOverlay(reflect.ValueOf(cfg).Elem(), overlay)
+	}
+
+	setProfileName(env)
+	if len(loaded) > 0 {
+		log.Printf("configuration: profile %q loaded %s", env, strings.Join(loaded, ", "))
+	} else {
+		log.Printf("configuration: profile %q found neither %s nor %s", env, basePath, profilePath)
+	}
+	return nil
+}
+
+// loadConfigFileIfExists loads path into cfg and appends it to loaded, unless
+// path does not exist, in which case it is silently skipped.
+func loadConfigFileIfExists(path string, cfg *LogServiceConfiguration, loaded *[]string) error {
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if err := loadConfigFile(path, cfg); err != nil {
+		return fmt.Errorf("configuration: loading %s: %w", path, err)
+	}
+	*loaded = append(*loaded, path)
+	return nil
+}
+
+// decodeOverlayIfExists decodes path into a generic key/value tree instead of
+// a LogServiceConfiguration, so applyOverlay can tell "key present with its
+// zero value" (e.g. `tls_enabled: false` overriding a base of true) apart
+// from "key absent" -- something a struct-to-struct merge can't distinguish
+// once the value is sitting in a Go zero-valued field.
+func decodeOverlayIfExists(path, ext string, loaded *[]string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	overlay := map[string]interface{}{}
+	switch strings.ToLower(ext) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &overlay)
+	case ".toml":
+		err = toml.Unmarshal(data, &overlay)
+	default:
+		err = fmt.Errorf("unsupported config file extension %q", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("configuration: loading %s: %w", path, err)
+	}
+
+	*loaded = append(*loaded, path)
+	return overlay, nil
+}
+
+// applyOverlay sets every field of v that is explicitly present as a key in
+// overlay, recursing into nested structs (e.g. Database) against the
+// matching nested map. Keys are matched against each field's yaml tag, which
+// in this package is kept identical to its toml tag.
+func applyOverlay(v reflect.Value, overlay map[string]interface{}) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := v.Field(i)
+		sf := t.Field(i)
+
+		key, ok := sf.Tag.Lookup("yaml")
+		if !ok {
+			continue
+		}
+		raw, present := overlay[key]
+		if !present {
+			continue
+		}
+
+		if field.Kind() == reflect.Struct && field.Type() != durationType {
+			if nested, ok := raw.(map[string]interface{}); ok {
+				applyOverlay(field, nested)
+			}
+			continue
+		}
+		setFieldFromOverlayValue(field, raw)
+	}
+}
+
+// setFieldFromOverlayValue assigns the already-decoded YAML/TOML value raw
+// to field, converting between the handful of dynamic types the yaml/toml
+// decoders produce for scalars (string, bool, int, int64, float64) and field's Go
+// type. Values of the wrong type are left untouched rather than panicking --
+// a malformed profile overlay shouldn't crash config loading.
+func setFieldFromOverlayValue(field reflect.Value, raw interface{}) {
+	if field.Type() == durationType {
+		if s, ok := raw.(string); ok {
+			if err := setFieldFromString(field, s); err != nil {
+				log.Printf("configuration: ignoring invalid overlay duration %q: %v", s, err)
+			}
+		}
+		return
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		if s, ok := raw.(string); ok {
+			field.SetString(s)
+		}
+	case reflect.Bool:
+		if b, ok := raw.(bool); ok {
+			field.SetBool(b)
+		}
+	case reflect.Int, reflect.Int64:
+		switch n := raw.(type) {
+		case int64:
+			field.SetInt(n)
+		case int:
+			field.SetInt(int64(n))
+		case float64:
+			field.SetInt(int64(n))
+		}
+	}
+}
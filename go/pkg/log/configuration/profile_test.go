@@ -0,0 +1,86 @@
+package configuration
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestApplyOverlayExplicitFalseOverridesTrue guards against the zero-value
+// merge bug: a profile overlay explicitly setting tls_enabled to false must
+// win even though false is also Go's zero value for bool, which a merge
+// based on src.IsZero() would have silently ignored.
+func TestApplyOverlayExplicitFalseOverridesTrue(t *testing.T) {
+	cfg := &LogServiceConfiguration{TLSEnabled: true, LogLevel: "info"}
+	overlay := map[string]interface{}{"tls_enabled": false}
+
+	applyOverlay(reflect.ValueOf(cfg).Elem(), overlay)
+
+	if cfg.TLSEnabled {
+		t.Fatal("TLSEnabled = true, want false after an explicit overlay override")
+	}
+	if cfg.LogLevel != "info" {
+		t.Fatalf("LogLevel = %q, want unchanged %q", cfg.LogLevel, "info")
+	}
+}
+
+func TestApplyOverlayLeavesAbsentKeysUntouched(t *testing.T) {
+	cfg := &LogServiceConfiguration{PORT: "50051", LogLevel: "info"}
+	overlay := map[string]interface{}{"log_level": "debug"}
+
+	applyOverlay(reflect.ValueOf(cfg).Elem(), overlay)
+
+	if cfg.LogLevel != "debug" {
+		t.Fatalf("LogLevel = %q, want %q", cfg.LogLevel, "debug")
+	}
+	if cfg.PORT != "50051" {
+		t.Fatalf("PORT = %q, want unchanged %q", cfg.PORT, "50051")
+	}
+}
+
+func TestApplyOverlayNestedStructOverridesOneField(t *testing.T) {
+	cfg := &LogServiceConfiguration{}
+	cfg.Database.Host = "base-host"
+	cfg.Database.Port = 5432
+
+	overlay := map[string]interface{}{
+		"database": map[string]interface{}{"host": "prod-host"},
+	}
+	applyOverlay(reflect.ValueOf(cfg).Elem(), overlay)
+
+	if cfg.Database.Host != "prod-host" {
+		t.Fatalf("Database.Host = %q, want %q", cfg.Database.Host, "prod-host")
+	}
+	if cfg.Database.Port != 5432 {
+		t.Fatalf("Database.Port = %d, want unchanged 5432", cfg.Database.Port)
+	}
+}
+
+// TestProfileNameResetsWhenCHROMAEnvIsUnset guards against ProfileName
+// sticking to a stale profile: loading once with CHROMA_ENV=prod and then
+// again with it unset must bring ProfileName back to "".
+func TestProfileNameResetsWhenCHROMAEnvIsUnset(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(configPath, []byte("port: \"50051\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(configFileEnvVar, configPath)
+
+	t.Setenv(profileEnvVar, "prod")
+	if _, err := LoadLogServiceConfiguration(nil); err != nil {
+		t.Fatalf("LoadLogServiceConfiguration() error = %v", err)
+	}
+	if got := ProfileName(); got != "prod" {
+		t.Fatalf("ProfileName() = %q, want %q", got, "prod")
+	}
+
+	t.Setenv(profileEnvVar, "")
+	if _, err := LoadLogServiceConfiguration(nil); err != nil {
+		t.Fatalf("LoadLogServiceConfiguration() error = %v", err)
+	}
+	if got := ProfileName(); got != "" {
+		t.Fatalf("ProfileName() = %q, want %q after CHROMA_ENV was unset", got, "")
+	}
+}
@@ -0,0 +1,153 @@
+package configuration
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// immutableFields cannot change without restarting the process. Watch
+// refuses to apply a reload that would change any of them and logs the
+// refusal instead; nothing is sent on the reload channel for it.
+var immutableFields = map[string]func(a, b *LogServiceConfiguration) bool{
+	"PORT":         func(a, b *LogServiceConfiguration) bool { return a.PORT != b.PORT },
+	"DATABASE_URL": func(a, b *LogServiceConfiguration) bool { return a.DATABASE_URL != b.DATABASE_URL },
+}
+
+var (
+	subscribersMu sync.Mutex
+	subscribers   []func(*LogServiceConfiguration)
+)
+
+// Subscribe registers fn to be called with every configuration snapshot that
+// Watch successfully applies. It is meant for runtime-tunable fields such as
+// log level, request timeouts, and sampling rates -- consumers that want a
+// callback instead of draining Watch's channel themselves.
+func Subscribe(fn func(*LogServiceConfiguration)) {
+	subscribersMu.Lock()
+	defer subscribersMu.Unlock()
+	subscribers = append(subscribers, fn)
+}
+
+func notifySubscribers(cfg *LogServiceConfiguration) {
+	subscribersMu.Lock()
+	fns := append([]func(*LogServiceConfiguration){}, subscribers...)
+	subscribersMu.Unlock()
+	for _, fn := range fns {
+		fn(cfg)
+	}
+}
+
+// Watch re-reads the config file named by CHROMA_LOG_CONFIG whenever the
+// process receives SIGHUP or the file changes on disk, and pushes each
+// resulting snapshot to the returned channel (and to any Subscribe
+// callbacks). It fails fast if no config file is configured, since there is
+// nothing to watch. A reload that would change an immutable field (PORT,
+// DATABASE_URL) is dropped with a log line rather than applied; operators
+// must restart the log service for those.
+func Watch(ctx context.Context) (<-chan *LogServiceConfiguration, error) {
+	path := os.Getenv(configFileEnvVar)
+	if path == "" {
+		return nil, fmt.Errorf("configuration: Watch requires %s to be set", configFileEnvVar)
+	}
+
+	current, err := LoadLogServiceConfiguration(nil)
+	if err != nil {
+		return nil, fmt.Errorf("configuration: loading initial configuration: %w", err)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("configuration: starting file watcher: %w", err)
+	}
+	// Watch the containing directory rather than path itself: Kubernetes
+	// updates a mounted ConfigMap by atomically renaming a new file over the
+	// old one, which fires a REMOVE/RENAME event for a directly-watched path
+	// and leaves fsnotify with nothing left to watch -- silently breaking
+	// reload after the very first edit. Watching the directory and filtering
+	// by filename survives that rename.
+	dir := filepath.Dir(path)
+	if err := watcher.Add(dir); err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("configuration: watching %s: %w", dir, err)
+	}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	out := make(chan *LogServiceConfiguration, 1)
+
+	go func() {
+		defer watcher.Close()
+		defer signal.Stop(hup)
+		defer close(out)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hup:
+				current = reload(ctx, path, current, out)
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) != filepath.Clean(path) {
+					continue
+				}
+				if event.Op&(fsnotify.Remove|fsnotify.Rename) != 0 {
+					// The file at path was removed or replaced (atomic
+					// rename-over, as Kubernetes does for ConfigMap updates);
+					// also watch the new file at path directly. Later renames
+					// over path are reported by the directory watch as well.
+					_ = watcher.Add(path)
+				}
+				if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) != 0 {
+					current = reload(ctx, path, current, out)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Printf("configuration: watcher error: %v", err)
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// reload re-reads path, compares the result against prev, and publishes it
+// on out and to subscribers -- unless the reload would change an immutable
+// field, in which case prev is returned unchanged and the reload is dropped
+// with a log line. The publish to out is done via select on ctx.Done(), not
+// a plain blocking send: Watch's event loop is single-goroutine, so a
+// consumer that doesn't drain out promptly would otherwise wedge the loop
+// and stop it from ever seeing ctx cancellation.
+func reload(ctx context.Context, path string, prev *LogServiceConfiguration, out chan<- *LogServiceConfiguration) *LogServiceConfiguration {
+	next, err := LoadLogServiceConfiguration(nil)
+	if err != nil {
+		log.Printf("configuration: reload of %s failed: %v", path, err)
+		return prev
+	}
+	for field, changed := range immutableFields {
+		if changed(prev, next) {
+			log.Printf("configuration: ignoring reload of %s: %s is immutable and cannot change without a restart", path, field)
+			return prev
+		}
+	}
+	notifySubscribers(next)
+	select {
+	case out <- next:
+		return next
+	case <-ctx.Done():
+		return prev
+	}
+}
@@ -0,0 +1,108 @@
+package configuration
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestImmutableFieldsDetectsPortChange(t *testing.T) {
+	a := &LogServiceConfiguration{PORT: "50051", DATABASE_URL: "same"}
+	b := &LogServiceConfiguration{PORT: "50052", DATABASE_URL: "same"}
+	if !immutableFields["PORT"](a, b) {
+		t.Fatal("immutableFields[\"PORT\"] = false, want true for a changed PORT")
+	}
+	if immutableFields["DATABASE_URL"](a, b) {
+		t.Fatal("immutableFields[\"DATABASE_URL\"] = true, want false for an unchanged DATABASE_URL")
+	}
+}
+
+func TestImmutableFieldsDetectsDatabaseURLChange(t *testing.T) {
+	a := &LogServiceConfiguration{PORT: "50051", DATABASE_URL: "postgresql://old"}
+	b := &LogServiceConfiguration{PORT: "50051", DATABASE_URL: "postgresql://new"}
+	if !immutableFields["DATABASE_URL"](a, b) {
+		t.Fatal("immutableFields[\"DATABASE_URL\"] = false, want true for a changed DATABASE_URL")
+	}
+}
+
+// TestReloadDoesNotBlockWhenConsumerIsSlowAndContextIsCancelled reproduces
+// the deadlock directly: with out already full (consumer not draining) and
+// ctx already cancelled, reload must return prev via the ctx.Done() branch
+// of its select rather than wedging on a blocking channel send.
+func TestReloadDoesNotBlockWhenConsumerIsSlowAndContextIsCancelled(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte("port: \"50051\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(configFileEnvVar, path)
+
+	prev := defaultLogServiceConfiguration()
+	out := make(chan *LogServiceConfiguration, 1)
+	out <- &LogServiceConfiguration{} // pre-fill so a blocking send would wedge
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	done := make(chan *LogServiceConfiguration, 1)
+	go func() { done <- reload(ctx, path, prev, out) }()
+
+	select {
+	case got := <-done:
+		if got != prev {
+			t.Fatalf("reload() = %v, want prev unchanged", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("reload() blocked instead of honoring ctx cancellation with a full out channel")
+	}
+}
+
+// TestWatchEndToEndReloadsOnSIGHUPAndStopsOnCancel drives Watch itself
+// rather than just the immutableFields map: a SIGHUP after an edit of a
+// runtime-tunable field (log_level) must produce an updated snapshot on the
+// returned channel, and cancelling ctx must close that channel.
+func TestWatchEndToEndReloadsOnSIGHUPAndStopsOnCancel(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte("port: \"50051\"\nlog_level: info\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(configFileEnvVar, path)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	out, err := Watch(ctx)
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte("port: \"50051\"\nlog_level: debug\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case cfg := <-out:
+		if cfg.LogLevel != "debug" {
+			t.Fatalf("LogLevel = %q, want %q", cfg.LogLevel, "debug")
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("timed out waiting for Watch to reload after SIGHUP")
+	}
+
+	cancel()
+	select {
+	case _, ok := <-out:
+		if ok {
+			t.Fatal("expected out to be closed after ctx cancellation")
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("timed out waiting for Watch to stop after ctx cancellation")
+	}
+}